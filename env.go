@@ -1,26 +1,128 @@
 package env
 
 import (
+	"encoding"
 	"fmt"
 	"os"
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
 const (
-	ENV         = "env"
-	TIME_LAYOUT = "timeLayout"
-	SEP         = ","
+	ENV                   = "env"
+	TIME_LAYOUT           = "timeLayout"
+	ENV_PREFIX            = "envPrefix"
+	ENV_SEPARATOR         = "envSeparator"
+	ENV_KEY_VAL_SEPARATOR = "envKeyValSeparator"
+	SEP                   = ","
 )
 
 type fieldData struct {
-	envVarName string
+	envVarName      string
+	defaultVal      string
+	strVal          string
+	timeLayout      string
+	separator       string
+	keyValSeparator string
+	required        bool
+	notEmpty        bool
+	expand          bool
+	parser          ParserFunc
+	lookup          envLookup
+	field           reflect.Value
+}
+
+// envTagOptions is the parsed form of an `env:"NAME,default=foo,required,notEmpty,expand"` tag.
+type envTagOptions struct {
+	name       string
 	defaultVal string
-	strVal     string
-	timeLayout string
-	field      reflect.Value
+	required   bool
+	notEmpty   bool
+	expand     bool
+}
+
+// parseEnvTag tokenizes the value of an `env` struct tag. The first,
+// unnamed token is the env var name. Every token after it, up to the first
+// recognised flag (`required`, `notEmpty` or `expand`), is part of the
+// default value: an optional `default=` prefix on the first of these tokens
+// is stripped, and the tokens are rejoined with SEP so a default value that
+// itself contains commas (e.g. a slice default under `envSeparator:","`, or
+// the older bare `env:"NAME,default"` form) round-trips intact. Once a flag
+// token is seen, every remaining token is a flag.
+func parseEnvTag(tag string) envTagOptions {
+	opts := envTagOptions{}
+	if tag == "" {
+		return opts
+	}
+	tokens := strings.Split(tag, SEP)
+	opts.name = tokens[0]
+	tokens = tokens[1:]
+
+	i := 0
+	var defaultParts []string
+	for ; i < len(tokens) && !isEnvTagFlag(tokens[i]); i++ {
+		part := tokens[i]
+		if i == 0 {
+			part = strings.TrimPrefix(part, "default=")
+		}
+		defaultParts = append(defaultParts, part)
+	}
+	opts.defaultVal = strings.Join(defaultParts, SEP)
+
+	for ; i < len(tokens); i++ {
+		switch tokens[i] {
+		case "required":
+			opts.required = true
+		case "notEmpty":
+			opts.notEmpty = true
+		case "expand":
+			opts.expand = true
+		}
+	}
+	return opts
+}
+
+// isEnvTagFlag reports whether token is one of the recognised bare `env` tag
+// flags, as opposed to part of a default value.
+func isEnvTagFlag(token string) bool {
+	return token == "required" || token == "notEmpty" || token == "expand"
+}
+
+// envLookup resolves the string value of an env var by name, falling back to
+// "" when unset. It is nil (meaning "use os.Getenv directly") everywhere
+// except LoadEnvVarsFromFiles, which layers file-provided values in.
+type envLookup func(name string) string
+
+// ParserFunc decodes the raw string value of an environment variable into a
+// value of some type T. It is the extension point used by RegisterParser for
+// types the loader doesn't know about out of the box, such as url.URL, net.IP
+// or a custom enum.
+type ParserFunc = func(string) (interface{}, error)
+
+var (
+	parserRegistryMu sync.RWMutex
+	parserRegistry   = map[reflect.Type]ParserFunc{}
+)
+
+// RegisterParser teaches the loader how to decode env values into t: every
+// field (or slice/map element) of that exact type is set by calling fn with
+// the raw string and assigning its result. Registering a parser for a type
+// that already has a custom parser replaces it. Safe for concurrent use.
+func RegisterParser(t reflect.Type, fn ParserFunc) {
+	parserRegistryMu.Lock()
+	defer parserRegistryMu.Unlock()
+	parserRegistry[t] = fn
+}
+
+// lookupParser returns the parser registered for t, if any.
+func lookupParser(t reflect.Type) (ParserFunc, bool) {
+	parserRegistryMu.RLock()
+	defer parserRegistryMu.RUnlock()
+	fn, ok := parserRegistry[t]
+	return fn, ok
 }
 
 type FieldErrorCode int
@@ -30,6 +132,10 @@ const (
 	ENV_VAR_PARSING_ERROR
 	DEFAULT_VALUE_NOT_SPECIFIED
 	DEFAULT_VALUE_PARSING_ERROR
+	SLICE_ELEMENT_PARSING_ERROR
+	MAP_ELEMENT_PARSING_ERROR
+	REQUIRED_FIELD_MISSING
+	NOT_EMPTY_VIOLATION
 	NIL FieldErrorCode = 0
 )
 
@@ -40,7 +146,7 @@ type FieldError struct {
 	ErrorMsg  string         // the combined error messages
 }
 
-func (e FieldError) AddError(errorCode FieldErrorCode, errorMsg string) {
+func (e *FieldError) AddError(errorCode FieldErrorCode, errorMsg string) {
 	e.ErrorCode += errorCode
 	e.ErrorMsg = fmt.Sprintf("%s\n\n%s", errorMsg, e.ErrorMsg)
 }
@@ -55,7 +161,7 @@ type StructError struct {
 	ErrorMsg    string
 }
 
-func (e StructError) Error() string {
+func (e *StructError) Error() string {
 	var errors []string
 	for _, fieldError := range e.FieldErrors {
 		errors = append(errors, fieldError.Error())
@@ -64,12 +170,54 @@ func (e StructError) Error() string {
 	return e.ErrorMsg
 }
 
+// Unwrap exposes each field's FieldError for use with errors.Is and
+// errors.As (Go 1.20 multi-error unwrapping).
+func (e *StructError) Unwrap() []error {
+	errs := make([]error, len(e.FieldErrors))
+	for i, fieldError := range e.FieldErrors {
+		errs[i] = fieldError
+	}
+	return errs
+}
+
+// HasCode reports whether any field failed with code set in its ErrorCode
+// bitmask, e.g. err.HasCode(env.REQUIRED_FIELD_MISSING).
+func (e *StructError) HasCode(code FieldErrorCode) bool {
+	for _, fieldError := range e.FieldErrors {
+		if fieldError.ErrorCode&code != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// FieldsWithCode returns the names of every field whose ErrorCode has code
+// set, e.g. to ask "which required vars are missing?".
+func (e *StructError) FieldsWithCode(code FieldErrorCode) []string {
+	var fields []string
+	for _, fieldError := range e.FieldErrors {
+		if fieldError.ErrorCode&code != 0 {
+			fields = append(fields, fieldError.FieldName)
+		}
+	}
+	return fields
+}
+
 //LoadEnvVars takes a pointer to a struct and loads the
 // values of each of its variables taged `env` from the
 // respective environment variable of the underlying OS.
 //The name of the environment variable corresponding to
 // a struct variable is determined from its StructTag
 // named 'env'.
+//Fields that are themselves structs (or pointers to structs, other than
+// time.Time) are walked recursively; an `envPrefix` tag on such a field is
+// prepended to every one of its descendants' `env` names.
+//The `env` tag accepts the grammar `NAME,default=VALUE,required,notEmpty,expand`:
+// `default=` sets a fallback value, `required` makes a missing variable a
+// hard error (unless `default=` is also set, in which case the default is
+// used and `required` only applies when no default is available either),
+// `notEmpty` rejects an empty resolved value, and `expand` runs os.Expand on
+// the raw value before parsing.
 func LoadEnvVars(structPtr interface{}) error {
 	return loadEnvVars(structPtr, false, false)
 }
@@ -86,6 +234,124 @@ func LoadEnvVarsTF(structPtr interface{}) error {
 	return loadEnvVars(structPtr, true, true)
 }
 
+// FileOverridesEnv controls the precedence between .env files and the OS
+// environment for LoadEnvVarsFromFiles. By default the OS environment wins,
+// so a value already exported in the shell is never shadowed by a file; set
+// this to true to let file values take priority instead.
+var FileOverridesEnv = false
+
+//LoadEnvVarsFromFiles behaves like LoadEnvVars, but additionally reads one or
+// more dotenv-format files (KEY=VALUE lines, `#` comments, single- and
+// double-quoted values) and uses them as a fallback for any `env` tag that
+// setField can't resolve from the OS environment. Later paths override
+// earlier ones. See FileOverridesEnv to flip which source takes priority.
+func LoadEnvVarsFromFiles(structPtr interface{}, paths ...string) error {
+	fileVars, err := mergeDotEnvFiles(paths)
+	if err != nil {
+		return err
+	}
+	return loadEnvVarsWithLookup(structPtr, false, false, fileLookup(fileVars))
+}
+
+// fileLookup builds an envLookup that resolves name from the OS environment
+// and fileVars, honouring FileOverridesEnv for precedence.
+func fileLookup(fileVars map[string]string) envLookup {
+	return func(name string) string {
+		osVal := os.Getenv(name)
+		fileVal := fileVars[name]
+		if FileOverridesEnv {
+			if fileVal != "" {
+				return fileVal
+			}
+			return osVal
+		}
+		if osVal != "" {
+			return osVal
+		}
+		return fileVal
+	}
+}
+
+// mergeDotEnvFiles parses each path in order and merges the results, with
+// later files overriding earlier ones for the same key.
+func mergeDotEnvFiles(paths []string) (map[string]string, error) {
+	merged := map[string]string{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		vars, err := parseDotEnv(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", path, err.Error())
+		}
+		for k, v := range vars {
+			merged[k] = v
+		}
+	}
+	return merged, nil
+}
+
+// parseDotEnv parses the contents of a dotenv-format file into a map of
+// KEY to VALUE. Blank lines and lines starting with `#` are ignored, a
+// leading `export ` on a line is stripped, and values may be unquoted
+// (trimmed, with a trailing `# comment` cut off), single-quoted (literal,
+// no escapes) or double-quoted (supporting \n, \t, \r, \" and \\ escapes).
+func parseDotEnv(data []byte) (map[string]string, error) {
+	result := map[string]string{}
+	for lineNo, raw := range strings.Split(string(data), "\n") {
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, rawVal, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE, got %q", lineNo+1, raw)
+		}
+		value, err := parseDotEnvValue(strings.TrimSpace(rawVal))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", lineNo+1, err.Error())
+		}
+		result[strings.TrimSpace(key)] = value
+	}
+	return result, nil
+}
+
+// parseDotEnvValue decodes a single dotenv value, handling quoting as
+// described on parseDotEnv.
+func parseDotEnvValue(raw string) (string, error) {
+	if raw == "" {
+		return "", nil
+	}
+	switch raw[0] {
+	case '"':
+		end := strings.LastIndexByte(raw, '"')
+		if end <= 0 {
+			return "", fmt.Errorf("unterminated double-quoted value %q", raw)
+		}
+		return unescapeDotEnvValue(raw[1:end]), nil
+	case '\'':
+		end := strings.LastIndexByte(raw, '\'')
+		if end <= 0 {
+			return "", fmt.Errorf("unterminated single-quoted value %q", raw)
+		}
+		return raw[1:end], nil
+	}
+	if idx := strings.IndexByte(raw, '#'); idx >= 0 {
+		raw = raw[:idx]
+	}
+	return strings.TrimSpace(raw), nil
+}
+
+var dotEnvEscapeReplacer = strings.NewReplacer(`\n`, "\n", `\t`, "\t", `\r`, "\r", `\"`, `"`, `\\`, `\`)
+
+// unescapeDotEnvValue resolves the backslash escapes recognised inside a
+// double-quoted dotenv value.
+func unescapeDotEnvValue(s string) string {
+	return dotEnvEscapeReplacer.Replace(s)
+}
+
 func LoadEnvVar(ptr interface{}, envVarName, defaultVal string) error {
 	return loadEnvVar(ptr, envVarName, defaultVal, false, "")
 }
@@ -94,61 +360,183 @@ func LoadEnvVarT(ptr interface{}, envVarName, defaultVal string, timeLayout stri
 	return loadEnvVar(ptr, envVarName, defaultVal, true, timeLayout)
 }
 
+// loadEnvVar, like loadEnvVarsWithLookup, always returns *StructError (or a
+// nil error) so callers can type-assert uniformly regardless of which loader
+// they used.
 func loadEnvVar(ptr interface{}, envVarName, defaultVal string, withTime bool, timeLayout string) error {
-	_fieldData := fieldData{envVarName, defaultVal, "", timeLayout, reflect.ValueOf(ptr).Elem()}
-	_fieldError := validateAndSet(&_fieldData, withTime)
-	if _fieldError != (FieldError{}) {
-		_fieldError.FieldName = envVarName
-		return _fieldError
+	_fieldData := fieldData{envVarName: envVarName, defaultVal: defaultVal, timeLayout: timeLayout, field: reflect.ValueOf(ptr).Elem()}
+	_fieldError := validateAndSet(&_fieldData, withTime, "", nil)
+	if _fieldError == (FieldError{}) {
+		return nil
 	}
-	return nil
+	_fieldError.FieldName = envVarName
+	return &StructError{FieldErrors: []FieldError{_fieldError}}
 }
 
 func loadEnvVars(structPtr interface{}, force bool, withTime bool) error {
+	return loadEnvVarsWithLookup(structPtr, force, withTime, nil)
+}
+
+func loadEnvVarsWithLookup(structPtr interface{}, force bool, withTime bool, lookup envLookup) error {
 	err := StructError{}
 	value := reflect.ValueOf(structPtr).Elem()
-	if value.Kind() == reflect.Struct {
-		for i := 0; i < value.Type().NumField(); i++ {
-			//For each field in the struct marked with `env` tag,
-			// get the value of corresponding environment variable for each
-			// field from the os and set it in the struct. If a field is
-			// not marked with `env` tag, the field is ignored.
-			envVar := strings.SplitN(value.Type().Field(i).Tag.Get(ENV), SEP, 2)
-			_fieldData := fieldData{}
-			if len(envVar) >= 1 {
-				_fieldData.envVarName = envVar[0]
-			}
-			if len(envVar) >= 2 {
-				_fieldData.defaultVal = envVar[1]
+	seen := map[reflect.Type]bool{value.Type(): true}
+	walkStruct(value, "", force, withTime, lookup, seen, &err)
+	if err.FieldErrors != nil {
+		return &err
+	}
+	return nil
+}
+
+// walkStruct iterates over the fields of value, which must be a reflect.Struct,
+// setting each `env`-tagged field from its corresponding environment variable.
+// Fields that are themselves structs (or pointers to structs, other than
+// time.Time) are descended into recursively, with prefix grown by that field's
+// `envPrefix` tag and prepended to every descendant's `env` tag. Field errors
+// encountered along the way are appended to err. seen holds every struct type
+// already on the current recursion path, so a self- or mutually-referential
+// pointer type (e.g. `type Node struct { Next *Node }`) is treated as a leaf
+// instead of recursed into forever.
+func walkStruct(value reflect.Value, prefix string, force bool, withTime bool, lookup envLookup, seen map[reflect.Type]bool, err *StructError) {
+	if value.Kind() != reflect.Struct {
+		return
+	}
+	for i := 0; i < value.Type().NumField(); i++ {
+		fieldType := value.Type().Field(i)
+		field := value.Field(i)
+
+		if nestedType, ok := nestedStructType(field); ok {
+			if seen[nestedType] {
+				continue
 			}
-			if (_fieldData.envVarName == "") && force {
-				_fieldData.envVarName = value.Type().Field(i).Name
+			nested, ok := nestedStruct(field)
+			if !ok {
+				continue
 			}
-			if withTime {
-				_fieldData.timeLayout = value.Type().Field(i).Tag.Get(TIME_LAYOUT)
+			childPrefix := prefix + fieldType.Tag.Get(ENV_PREFIX)
+			childSeen := make(map[reflect.Type]bool, len(seen)+1)
+			for t := range seen {
+				childSeen[t] = true
 			}
-			_fieldData.field = value.Field(i)
-			if _fieldData.envVarName != "" {
-				_fieldError := validateAndSet(&_fieldData, withTime)
-				if _fieldError != (FieldError{}) {
-					_fieldError.FieldName = value.Type().Field(i).Name
-					err.FieldErrors = append(err.FieldErrors, _fieldError)
-				}
+			childSeen[nestedType] = true
+			walkStruct(nested, childPrefix, force, withTime, lookup, childSeen, err)
+			continue
+		}
+
+		//For each field in the struct marked with `env` tag,
+		// get the value of corresponding environment variable for each
+		// field from the os and set it in the struct. If a field is
+		// not marked with `env` tag, the field is ignored.
+		tagOpts := parseEnvTag(fieldType.Tag.Get(ENV))
+		_fieldData := fieldData{}
+		_fieldData.envVarName = tagOpts.name
+		_fieldData.defaultVal = tagOpts.defaultVal
+		_fieldData.required = tagOpts.required
+		_fieldData.notEmpty = tagOpts.notEmpty
+		_fieldData.expand = tagOpts.expand
+		if (_fieldData.envVarName == "") && force {
+			_fieldData.envVarName = fieldType.Name
+		}
+		if withTime {
+			_fieldData.timeLayout = fieldType.Tag.Get(TIME_LAYOUT)
+		}
+		_fieldData.separator = fieldType.Tag.Get(ENV_SEPARATOR)
+		_fieldData.keyValSeparator = fieldType.Tag.Get(ENV_KEY_VAL_SEPARATOR)
+		_fieldData.field = field
+		if _fieldData.envVarName != "" {
+			_fieldError := validateAndSet(&_fieldData, withTime, prefix, lookup)
+			if _fieldError != (FieldError{}) {
+				_fieldError.FieldName = fieldType.Name
+				err.FieldErrors = append(err.FieldErrors, _fieldError)
 			}
 		}
 	}
-	if err.FieldErrors != nil {
-		return err
+}
+
+// nestedStructType reports the struct type field would be recursed into (the
+// field's own type for a reflect.Struct, or the pointee type for a
+// reflect.Ptr to one), without allocating anything. time.Time and any leaf
+// type recognised by isLeafType are excluded.
+func nestedStructType(field reflect.Value) (reflect.Type, bool) {
+	if field.Kind() == reflect.Ptr && field.Type().Elem().Kind() == reflect.Struct && !isLeafType(field.Type().Elem()) {
+		return field.Type().Elem(), true
 	}
-	return nil
+	if field.Kind() == reflect.Struct && !isLeafType(field.Type()) {
+		return field.Type(), true
+	}
+	return nil, false
+}
+
+// nestedStruct reports whether field should be recursed into rather than set
+// directly: a reflect.Struct (other than time.Time), or a reflect.Ptr to one.
+// Nil pointers are allocated with reflect.New so recursion has somewhere to
+// write. The returned Value is always the addressable struct to recurse into.
+// Callers should check nestedStructType first to avoid allocating a pointer
+// that a cycle guard will immediately discard.
+func nestedStruct(field reflect.Value) (reflect.Value, bool) {
+	if _, ok := nestedStructType(field); !ok {
+		return reflect.Value{}, false
+	}
+	if field.Kind() == reflect.Ptr {
+		if !field.CanSet() {
+			return reflect.Value{}, false
+		}
+		if field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+		return field.Elem(), true
+	}
+	return field, true
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// isLeafType reports whether t should be set directly from a single env
+// string rather than recursed into as a struct: time.Time, any type with a
+// RegisterParser entry, or any type whose pointer implements
+// encoding.TextUnmarshaler.
+func isLeafType(t reflect.Type) bool {
+	if t == reflect.TypeOf(time.Time{}) {
+		return true
+	}
+	if _, ok := lookupParser(t); ok {
+		return true
+	}
+	return reflect.PtrTo(t).Implements(textUnmarshalerType)
 }
 
 //validateAndSet checks whether the field is valid and is changable
 // and sets its value only if the above condition is true. Else, it ignores the field.
+// prefix, grown by any ancestor's `envPrefix` tag, is prepended to envVarName
+// before it is looked up. lookup, if non-nil, resolves the env var instead of
+// os.Getenv (used by LoadEnvVarsFromFiles to layer .env files in).
 //An error is returned if the environment variable is not found or if there are any parsing errors.
-func validateAndSet(_fieldData *fieldData, withTime bool) FieldError {
+func validateAndSet(_fieldData *fieldData, withTime bool, prefix string, lookup envLookup) FieldError {
+	if _fieldData.envVarName != "" {
+		_fieldData.envVarName = prefix + _fieldData.envVarName
+	}
+	_fieldData.lookup = lookup
 	if _fieldData.field.IsValid() {
 		if _fieldData.field.CanSet() {
+			// Custom decoding (RegisterParser / TextUnmarshaler) takes priority
+			// over the Kind()-based defaults below, since a named type such as
+			// `type myLevel int` would otherwise always match reflect.Int and
+			// never reach its registered parser.
+			if fn, ok := lookupParser(_fieldData.field.Type()); ok {
+				_fieldData.parser = fn
+				return setField(_fieldData, setParserField)
+			}
+			if withTime && (_fieldData.field.Type() == reflect.TypeOf(time.Time{})) {
+				return setField(_fieldData, setTimeField)
+			}
+			if _fieldData.field.Type() == reflect.TypeOf(time.Duration(0)) {
+				return setField(_fieldData, setDurationField)
+			}
+			if _fieldData.field.CanAddr() {
+				if _, ok := _fieldData.field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+					return setField(_fieldData, setTextUnmarshalerField)
+				}
+			}
 			switch _fieldData.field.Kind() {
 			case reflect.String:
 				return setField(_fieldData, setStrField)
@@ -158,9 +546,10 @@ func validateAndSet(_fieldData *fieldData, withTime bool) FieldError {
 				return setField(_fieldData, setFloatField)
 			case reflect.Bool:
 				return setField(_fieldData, setBoolField)
-			}
-			if withTime && (_fieldData.field.Type() == reflect.TypeOf(time.Time{})) {
-				return setField(_fieldData, setTimeField)
+			case reflect.Slice:
+				return setFieldWithCode(_fieldData, setSliceField, SLICE_ELEMENT_PARSING_ERROR)
+			case reflect.Map:
+				return setFieldWithCode(_fieldData, setMapField, MAP_ELEMENT_PARSING_ERROR)
 			}
 		}
 	}
@@ -170,15 +559,39 @@ func validateAndSet(_fieldData *fieldData, withTime bool) FieldError {
 // setField uses the function setFunc to set various types fo values to fieldValue
 // It handles the various possible error conditions and returns a fieldError instance
 func setField(_fieldData *fieldData, setFunc func(*fieldData, string) error) FieldError {
-	_fieldData.strVal = os.Getenv(_fieldData.envVarName)
+	return setFieldWithCode(_fieldData, setFunc, ENV_VAR_PARSING_ERROR)
+}
+
+// setFieldWithCode behaves like setField, but reports parsingErrorCode instead
+// of ENV_VAR_PARSING_ERROR when setFunc fails on the value read from the
+// environment. This lets Kinds with richer failure modes (e.g. slice/map
+// element parsing) surface a more specific FieldErrorCode bit.
+func setFieldWithCode(_fieldData *fieldData, setFunc func(*fieldData, string) error, parsingErrorCode FieldErrorCode) FieldError {
+	if _fieldData.lookup != nil {
+		_fieldData.strVal = _fieldData.lookup(_fieldData.envVarName)
+	} else {
+		_fieldData.strVal = os.Getenv(_fieldData.envVarName)
+	}
 	fe := FieldError{}
+	if _fieldData.strVal == "" && _fieldData.required && _fieldData.defaultVal == "" {
+		fe.AddError(REQUIRED_FIELD_MISSING, fmt.Sprintf("Required env variable %q not found.", _fieldData.envVarName))
+		return fe
+	}
 	var err error
 	if _fieldData.strVal != "" {
-		err = setFunc(_fieldData, _fieldData.strVal)
+		value := _fieldData.strVal
+		if _fieldData.expand {
+			value = os.Expand(value, os.Getenv)
+		}
+		if _fieldData.notEmpty && value == "" {
+			fe.AddError(NOT_EMPTY_VIOLATION, fmt.Sprintf("Env variable %q resolved to an empty value.", _fieldData.envVarName))
+			return fe
+		}
+		err = setFunc(_fieldData, value)
 		if err == nil {
 			return fe
 		}
-		fe.AddError(ENV_VAR_PARSING_ERROR, fmt.Sprintf("%s\n%s", "Error parsing env variable.Trying to set default value...", err.Error()))
+		fe.AddError(parsingErrorCode, fmt.Sprintf("%s\n%s", "Error parsing env variable.Trying to set default value...", err.Error()))
 	} else {
 		fe.AddError(ENV_VAR_NOT_FOUND, "Env Variable not found. Trying to set default value")
 	}
@@ -245,10 +658,183 @@ func setBoolField(_fieldData *fieldData, valueToSet string) error {
 //The function parses the string to time.Time and returns any error
 // incurred while parsing the field value
 func setTimeField(_fieldData *fieldData, valueToSet string) error {
-	timeVal, err := time.Parse(_fieldData.timeLayout, valueToSet)
+	if _fieldData.timeLayout != "" {
+		timeVal, err := time.Parse(_fieldData.timeLayout, valueToSet)
+		if err != nil {
+			return err
+		}
+		_fieldData.field.Set(reflect.ValueOf(timeVal))
+		return nil
+	}
+	timeVal, err := parseTimeAnyFormat(valueToSet)
 	if err != nil {
 		return err
 	}
 	_fieldData.field.Set(reflect.ValueOf(timeVal))
 	return nil
 }
+
+// TimeParserFormats are the layouts tried, in order, to parse a time.Time
+// field whose `timeLayout` tag is empty. Prepend your own layout to have it
+// tried before these.
+var TimeParserFormats = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	time.RFC1123Z,
+	time.RFC822Z,
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// parseTimeAnyFormat parses value as a time.Time without a fixed layout: an
+// all-digit value is treated as a Unix epoch timestamp (seconds for up to 10
+// digits, milliseconds beyond that), otherwise each of TimeParserFormats is
+// tried in order and the first successful parse wins.
+func parseTimeAnyFormat(value string) (time.Time, error) {
+	if isAllDigits(value) {
+		if epoch, err := strconv.ParseInt(value, 10, 64); err == nil {
+			if len(value) > 10 {
+				return time.UnixMilli(epoch), nil
+			}
+			return time.Unix(epoch, 0), nil
+		}
+	}
+	for _, layout := range TimeParserFormats {
+		if timeVal, err := time.Parse(layout, value); err == nil {
+			return timeVal, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("could not parse %q as a time using any known layout", value)
+}
+
+// isAllDigits reports whether s is non-empty and consists only of ASCII digits.
+func isAllDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+//setDurationField sets a time.Duration value to the field assuming that the
+// canSet() for the field is true, via time.ParseDuration.
+func setDurationField(_fieldData *fieldData, valueToSet string) error {
+	durationVal, err := time.ParseDuration(valueToSet)
+	if err != nil {
+		return err
+	}
+	_fieldData.field.SetInt(int64(durationVal))
+	return nil
+}
+
+//setSliceField sets a slice value to the field assuming that the canSet()
+// for the field is true. valueToSet is split on _fieldData.separator
+// (defaulting to SEP) and each element is parsed according to the slice's
+// element Kind, using the same setXXXField helpers as scalar fields.
+//The function returns an error naming the index of the first element that
+// failed to parse.
+func setSliceField(_fieldData *fieldData, valueToSet string) error {
+	sep := _fieldData.separator
+	if sep == "" {
+		sep = SEP
+	}
+	parts := strings.Split(valueToSet, sep)
+	elemType := _fieldData.field.Type().Elem()
+	slice := reflect.MakeSlice(_fieldData.field.Type(), len(parts), len(parts))
+	for i, part := range parts {
+		elemData := fieldData{timeLayout: _fieldData.timeLayout, field: slice.Index(i)}
+		if err := setElemField(&elemData, elemType, part); err != nil {
+			return fmt.Errorf("error parsing element at index %d: %s", i, err.Error())
+		}
+	}
+	_fieldData.field.Set(slice)
+	return nil
+}
+
+//setMapField sets a string-keyed map value to the field assuming that the
+// canSet() for the field is true. valueToSet is split into key/value pairs on
+// _fieldData.separator (defaulting to SEP), each pair is split on
+// _fieldData.keyValSeparator (defaulting to ":"), and every value is parsed
+// according to the map's value Kind.
+//The function returns an error naming the pair whose value failed to parse.
+func setMapField(_fieldData *fieldData, valueToSet string) error {
+	if _fieldData.field.Type().Key().Kind() != reflect.String {
+		return fmt.Errorf("unsupported map key type %s: only string-keyed maps are supported", _fieldData.field.Type().Key())
+	}
+	sep := _fieldData.separator
+	if sep == "" {
+		sep = SEP
+	}
+	kvSep := _fieldData.keyValSeparator
+	if kvSep == "" {
+		kvSep = ":"
+	}
+	valueType := _fieldData.field.Type().Elem()
+	result := reflect.MakeMap(_fieldData.field.Type())
+	for i, pair := range strings.Split(valueToSet, sep) {
+		kv := strings.SplitN(pair, kvSep, 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("error parsing element at index %d: expected a %q separated key%svalue pair, got %q", i, kvSep, kvSep, pair)
+		}
+		elemValue := reflect.New(valueType).Elem()
+		elemData := fieldData{timeLayout: _fieldData.timeLayout, field: elemValue}
+		if err := setElemField(&elemData, valueType, kv[1]); err != nil {
+			return fmt.Errorf("error parsing element at index %d: %s", i, err.Error())
+		}
+		result.SetMapIndex(reflect.ValueOf(kv[0]), elemValue)
+	}
+	_fieldData.field.Set(result)
+	return nil
+}
+
+//setElemField dispatches to the right setXXXField helper for a slice or map
+// element based on its Kind, mirroring the Kind switch in validateAndSet.
+func setElemField(elemData *fieldData, elemType reflect.Type, valueToSet string) error {
+	if fn, ok := lookupParser(elemType); ok {
+		elemData.parser = fn
+		return setParserField(elemData, valueToSet)
+	}
+	switch elemType.Kind() {
+	case reflect.String:
+		return setStrField(elemData, valueToSet)
+	case reflect.Int:
+		return setIntField(elemData, valueToSet)
+	case reflect.Float64:
+		return setFloatField(elemData, valueToSet)
+	case reflect.Bool:
+		return setBoolField(elemData, valueToSet)
+	}
+	if elemType == reflect.TypeOf(time.Time{}) {
+		return setTimeField(elemData, valueToSet)
+	}
+	return fmt.Errorf("unsupported element type %s", elemType)
+}
+
+//setParserField sets the field by calling _fieldData.parser, the ParserFunc
+// registered via RegisterParser for the field's type, and assigning its
+// result. It returns an error if the parser fails or returns a value that
+// isn't assignable to the field.
+func setParserField(_fieldData *fieldData, valueToSet string) error {
+	val, err := _fieldData.parser(valueToSet)
+	if err != nil {
+		return err
+	}
+	rv := reflect.ValueOf(val)
+	if !rv.IsValid() || !rv.Type().AssignableTo(_fieldData.field.Type()) {
+		return fmt.Errorf("parser for %s returned incompatible value %v", _fieldData.field.Type(), val)
+	}
+	_fieldData.field.Set(rv)
+	return nil
+}
+
+//setTextUnmarshalerField sets the field via the encoding.TextUnmarshaler its
+// pointer implements, for types (e.g. net.IP) that have no built-in or
+// registered parser but decode themselves from text.
+func setTextUnmarshalerField(_fieldData *fieldData, valueToSet string) error {
+	unmarshaler := _fieldData.field.Addr().Interface().(encoding.TextUnmarshaler)
+	return unmarshaler.UnmarshalText([]byte(valueToSet))
+}
@@ -5,6 +5,8 @@ import "os"
 import "github.com/matryer/is"
 import "time"
 import "fmt"
+import "reflect"
+import "strconv"
 
 type envVarOs struct {
 	name  string
@@ -86,6 +88,313 @@ func TestLoadEnvVar_WithFloatPtrDefaultValue_ShouldNotSetVariables(t *testing.T)
 	is.Equal(var1, expected)
 }
 
+func TestLoadEnvVars_WithSliceField_ShouldSplitAndSetVars(t *testing.T) {
+	is := is.New(t)
+	os.Setenv("TEST_SLICE_1", "1|2|3")
+	type Type3 struct {
+		Var1 []int `env:"TEST_SLICE_1" envSeparator:"|"`
+	}
+	struct1 := Type3{}
+	LoadEnvVars(&struct1)
+	struct1_expected := Type3{[]int{1, 2, 3}}
+	is.Equal(struct1, struct1_expected)
+}
+
+func TestLoadEnvVars_WithMapField_ShouldSplitAndSetVars(t *testing.T) {
+	is := is.New(t)
+	os.Setenv("TEST_MAP_1", "a=1,b=2")
+	type Type4 struct {
+		Var1 map[string]int `env:"TEST_MAP_1" envKeyValSeparator:"="`
+	}
+	struct1 := Type4{}
+	LoadEnvVars(&struct1)
+	struct1_expected := Type4{map[string]int{"a": 1, "b": 2}}
+	is.Equal(struct1, struct1_expected)
+}
+
+func TestLoadEnvVars_WithNonStringKeyedMap_ShouldReturnErrorNotPanic(t *testing.T) {
+	is := is.New(t)
+	os.Setenv("TEST_MAP_INT_KEY", "1=one,2=two")
+	type Type18 struct {
+		Var1 map[int]string `env:"TEST_MAP_INT_KEY" envKeyValSeparator:"="`
+	}
+	struct1 := Type18{}
+	err := LoadEnvVars(&struct1)
+	structErr, ok := err.(*StructError)
+	is.True(ok)
+	is.Equal(len(structErr.FieldErrors), 1)
+	is.Equal(structErr.FieldErrors[0].ErrorCode, MAP_ELEMENT_PARSING_ERROR|DEFAULT_VALUE_NOT_SPECIFIED)
+}
+
+type level struct {
+	name string
+}
+
+func (l *level) UnmarshalText(text []byte) error {
+	l.name = string(text)
+	return nil
+}
+
+type celsius float64
+
+func TestLoadEnvVars_WithRegisteredParser_ShouldUseParser(t *testing.T) {
+	is := is.New(t)
+	os.Setenv("TEST_TEMP_1", "36.6")
+	RegisterParser(reflect.TypeOf(celsius(0)), func(value string) (interface{}, error) {
+		f, err := strconv.ParseFloat(value, 64)
+		return celsius(f), err
+	})
+	type Type5 struct {
+		Var1 celsius `env:"TEST_TEMP_1"`
+	}
+	struct1 := Type5{}
+	LoadEnvVars(&struct1)
+	struct1_expected := Type5{celsius(36.6)}
+	is.Equal(struct1, struct1_expected)
+}
+
+type myLevel int
+
+func TestLoadEnvVars_WithRegisteredParserOnNamedIntType_ShouldUseParserNotStrconv(t *testing.T) {
+	is := is.New(t)
+	os.Setenv("TEST_LEVEL_NAMED_INT", "high")
+	RegisterParser(reflect.TypeOf(myLevel(0)), func(value string) (interface{}, error) {
+		levels := map[string]myLevel{"low": 0, "medium": 1, "high": 2}
+		return levels[value], nil
+	})
+	type Type17 struct {
+		Var1 myLevel `env:"TEST_LEVEL_NAMED_INT"`
+	}
+	struct1 := Type17{}
+	err := LoadEnvVars(&struct1)
+	is.NoErr(err)
+	struct1_expected := Type17{myLevel(2)}
+	is.Equal(struct1, struct1_expected)
+}
+
+func TestLoadEnvVars_WithTextUnmarshaler_ShouldUnmarshalText(t *testing.T) {
+	is := is.New(t)
+	os.Setenv("TEST_LEVEL_1", "debug")
+	type Type6 struct {
+		Var1 level `env:"TEST_LEVEL_1"`
+	}
+	struct1 := Type6{}
+	LoadEnvVars(&struct1)
+	struct1_expected := Type6{level{"debug"}}
+	is.Equal(struct1, struct1_expected)
+}
+
+func TestLoadEnvVars_WithRequiredFieldMissing_ShouldReturnRequiredError(t *testing.T) {
+	is := is.New(t)
+	os.Unsetenv("TEST_REQUIRED_MISSING")
+	type Type9 struct {
+		Var1 string `env:"TEST_REQUIRED_MISSING,required"`
+	}
+	struct1 := Type9{}
+	err := LoadEnvVars(&struct1)
+	structErr, ok := err.(*StructError)
+	is.True(ok)
+	is.Equal(len(structErr.FieldErrors), 1)
+	is.Equal(structErr.FieldErrors[0].ErrorCode, REQUIRED_FIELD_MISSING)
+}
+
+func TestLoadEnvVars_WithRequiredFieldMissingAndDefaultSet_ShouldUseDefault(t *testing.T) {
+	is := is.New(t)
+	os.Unsetenv("TEST_REQUIRED_WITH_DEFAULT")
+	type Type19 struct {
+		Var1 string `env:"TEST_REQUIRED_WITH_DEFAULT,default=fallback,required"`
+	}
+	struct1 := Type19{}
+	LoadEnvVars(&struct1)
+	struct1_expected := Type19{"fallback"}
+	is.Equal(struct1, struct1_expected)
+}
+
+func TestLoadEnvVars_WithCommaInBareDefault_ShouldKeepDefaultIntact(t *testing.T) {
+	is := is.New(t)
+	os.Unsetenv("TEST_DEFAULT_COMMA")
+	type Type15 struct {
+		Var1 string `env:"TEST_DEFAULT_COMMA,hello,world"`
+	}
+	struct1 := Type15{}
+	LoadEnvVars(&struct1)
+	struct1_expected := Type15{"hello,world"}
+	is.Equal(struct1, struct1_expected)
+}
+
+func TestLoadEnvVars_WithCommaInSliceDefault_ShouldSplitFullDefault(t *testing.T) {
+	is := is.New(t)
+	os.Unsetenv("TEST_TAGS_DEFAULT")
+	type Type16 struct {
+		Var1 []string `env:"TEST_TAGS_DEFAULT,default=a,b,c" envSeparator:","`
+	}
+	struct1 := Type16{}
+	LoadEnvVars(&struct1)
+	struct1_expected := Type16{[]string{"a", "b", "c"}}
+	is.Equal(struct1, struct1_expected)
+}
+
+func TestLoadEnvVars_WithExpandTag_ShouldExpandSubVars(t *testing.T) {
+	is := is.New(t)
+	os.Setenv("TEST_HOST", "localhost")
+	os.Setenv("TEST_PORT", "8080")
+	os.Setenv("TEST_URL", "http://${TEST_HOST}:${TEST_PORT}")
+	type Type10 struct {
+		Var1 string `env:"TEST_URL,expand"`
+	}
+	struct1 := Type10{}
+	LoadEnvVars(&struct1)
+	struct1_expected := Type10{"http://localhost:8080"}
+	is.Equal(struct1, struct1_expected)
+}
+
+func TestLoadEnvVars_WithNotEmptyAndUnresolvedExpand_ShouldReturnNotEmptyError(t *testing.T) {
+	is := is.New(t)
+	os.Setenv("TEST_EMPTY_AFTER_EXPAND", "${TEST_UNSET_VAR}")
+	os.Unsetenv("TEST_UNSET_VAR")
+	type Type11 struct {
+		Var1 string `env:"TEST_EMPTY_AFTER_EXPAND,expand,notEmpty"`
+	}
+	struct1 := Type11{}
+	err := LoadEnvVars(&struct1)
+	structErr, ok := err.(*StructError)
+	is.True(ok)
+	is.Equal(len(structErr.FieldErrors), 1)
+	is.Equal(structErr.FieldErrors[0].ErrorCode, NOT_EMPTY_VIOLATION)
+}
+
+func TestStructError_HasCodeAndFieldsWithCode_ShouldReportRequiredFields(t *testing.T) {
+	is := is.New(t)
+	os.Unsetenv("TEST_REQUIRED_MISSING_1")
+	os.Unsetenv("TEST_REQUIRED_MISSING_2")
+	type Type12 struct {
+		Var1 string `env:"TEST_REQUIRED_MISSING_1,required"`
+		Var2 string `env:"TEST_REQUIRED_MISSING_2,required"`
+	}
+	struct1 := Type12{}
+	err := LoadEnvVars(&struct1)
+	structErr, ok := err.(*StructError)
+	is.True(ok)
+	is.True(structErr.HasCode(REQUIRED_FIELD_MISSING))
+	is.Equal(len(structErr.FieldsWithCode(REQUIRED_FIELD_MISSING)), 2)
+	is.True(len(structErr.Unwrap()) == 2)
+}
+
+func TestLoadEnvVarsFromFiles_WithDotEnvFile_ShouldSetVars(t *testing.T) {
+	is := is.New(t)
+	dir := t.TempDir()
+	path := dir + "/.env"
+	contents := "# a comment\nFILE_VAR_1=hello\nFILE_VAR_2=\"with spaces\"\nexport FILE_VAR_3='literal $NOT_EXPANDED'\n"
+	os.WriteFile(path, []byte(contents), 0644)
+	type Type7 struct {
+		Var1 string `env:"FILE_VAR_1"`
+		Var2 string `env:"FILE_VAR_2"`
+		Var3 string `env:"FILE_VAR_3"`
+	}
+	struct1 := Type7{}
+	err := LoadEnvVarsFromFiles(&struct1, path)
+	is.NoErr(err)
+	struct1_expected := Type7{"hello", "with spaces", "literal $NOT_EXPANDED"}
+	is.Equal(struct1, struct1_expected)
+}
+
+func TestLoadEnvVarsFromFiles_WithOsEnvSet_ShouldPreferOsEnv(t *testing.T) {
+	is := is.New(t)
+	os.Setenv("TEST_1", "FROM_OS")
+	dir := t.TempDir()
+	path := dir + "/.env"
+	os.WriteFile(path, []byte("TEST_1=FROM_FILE\n"), 0644)
+	type Type8 struct {
+		Var1 string `env:"TEST_1"`
+	}
+	struct1 := Type8{}
+	LoadEnvVarsFromFiles(&struct1, path)
+	struct1_expected := Type8{"FROM_OS"}
+	is.Equal(struct1, struct1_expected)
+}
+
+func TestLoadEnvVars_WithNestedStruct_ShouldSetVarsWithPrefix(t *testing.T) {
+	is := is.New(t)
+	os.Setenv("DB_TEST_1", "DB_VALUE_1")
+	os.Setenv("DB_TEST_6", "16")
+	type DBConfig struct {
+		Host string `env:"TEST_1"`
+		Port int    `env:"TEST_6"`
+	}
+	type Config struct {
+		DB DBConfig `envPrefix:"DB_"`
+	}
+	config := Config{}
+	LoadEnvVars(&config)
+	config_expected := Config{DBConfig{"DB_VALUE_1", 16}}
+	is.Equal(config, config_expected)
+}
+
+func TestLoadEnvVars_WithNestedStructPtr_ShouldAllocateAndSetVars(t *testing.T) {
+	is := is.New(t)
+	type DBConfig struct {
+		Host string `env:"TEST_1"`
+	}
+	type Config struct {
+		DB *DBConfig `envPrefix:"DB_"`
+	}
+	config := Config{}
+	LoadEnvVars(&config)
+	is.Equal(config.DB.Host, "DB_VALUE_1")
+}
+
+func TestLoadEnvVars_WithSelfReferentialStructPtr_ShouldNotRecurseForever(t *testing.T) {
+	is := is.New(t)
+	os.Setenv("TEST_SELF_REF_1", "VALUE_1")
+	type Node struct {
+		Val  string `env:"TEST_SELF_REF_1"`
+		Next *Node
+	}
+	node := Node{}
+	done := make(chan error, 1)
+	go func() {
+		done <- LoadEnvVars(&node)
+	}()
+	select {
+	case err := <-done:
+		is.NoErr(err)
+		is.Equal(node.Val, "VALUE_1")
+		is.True(node.Next == nil)
+	case <-time.After(2 * time.Second):
+		t.Fatal("LoadEnvVars did not return for a self-referential struct type")
+	}
+}
+
+func TestLoadEnvVars_WithDurationField_ShouldParseDuration(t *testing.T) {
+	is := is.New(t)
+	os.Setenv("TEST_DURATION_1", "90s")
+	type Type13 struct {
+		Var1 time.Duration `env:"TEST_DURATION_1"`
+	}
+	struct1 := Type13{}
+	LoadEnvVars(&struct1)
+	struct1_expected := Type13{90 * time.Second}
+	is.Equal(struct1, struct1_expected)
+}
+
+func TestLoadEnvVarsT_WithNoTimeLayout_ShouldTryKnownFormats(t *testing.T) {
+	is := is.New(t)
+	os.Setenv("TEST_TIME_RFC3339", "2017-11-01T00:00:00Z")
+	os.Setenv("TEST_TIME_DATE_ONLY", "2017-11-01")
+	os.Setenv("TEST_TIME_EPOCH", "1509494400")
+	type Type14 struct {
+		Var1 time.Time `env:"TEST_TIME_RFC3339"`
+		Var2 time.Time `env:"TEST_TIME_DATE_ONLY"`
+		Var3 time.Time `env:"TEST_TIME_EPOCH"`
+	}
+	struct1 := Type14{}
+	LoadEnvVarsT(&struct1)
+	expected := time.Date(2017, time.November, 1, 0, 0, 0, 0, time.UTC)
+	is.True(struct1.Var1.Equal(expected))
+	is.True(struct1.Var2.Equal(expected))
+	is.True(struct1.Var3.Equal(expected))
+}
+
 func TestLoadEnvVarT_WithTimePtr_ShouldNotSetVariables(t *testing.T) {
 	is := is.New(t)
 	var var1 time.Time